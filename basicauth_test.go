@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasicAuthMiddleware(t *testing.T) {
+	var okHandler http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	Convey("The basic auth middleware should reject requests without valid credentials", t, func() {
+		m := NewBasicAuthMiddleware(BasicAuthOptions{
+			Checker: StaticCredentials("alice", "hunter2"),
+		})(okHandler)
+
+		record := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		m.ServeHTTP(record, req)
+
+		So(record.Code, ShouldEqual, http.StatusUnauthorized)
+		So(record.Header().Get("WWW-Authenticate"), ShouldEqual, `Basic realm="Restricted"`)
+
+		Convey("A request with valid credentials should be allowed through", func() {
+			record := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.SetBasicAuth("alice", "hunter2")
+			m.ServeHTTP(record, req)
+
+			So(record.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("A request with an invalid password should be rejected", func() {
+			record := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.SetBasicAuth("alice", "wrong")
+			m.ServeHTTP(record, req)
+
+			So(record.Code, ShouldEqual, http.StatusUnauthorized)
+		})
+	})
+
+	Convey("A custom realm should appear in the WWW-Authenticate header", t, func() {
+		m := NewBasicAuthMiddleware(BasicAuthOptions{
+			Checker: StaticCredentials("alice", "hunter2"),
+			Realm:   "Admin Area",
+		})(okHandler)
+
+		record := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		m.ServeHTTP(record, req)
+
+		So(record.Header().Get("WWW-Authenticate"), ShouldEqual, `Basic realm="Admin Area"`)
+	})
+
+	Convey("PathPrefixes should scope the middleware to matching paths", t, func() {
+		m := NewBasicAuthMiddleware(BasicAuthOptions{
+			Checker:      StaticCredentials("alice", "hunter2"),
+			PathPrefixes: []string{"/admin"},
+		})(okHandler)
+
+		Convey("Requests outside the scoped prefix pass through unauthenticated", func() {
+			record := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/public", nil)
+			m.ServeHTTP(record, req)
+
+			So(record.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("Requests inside the scoped prefix require credentials", func() {
+			record := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/admin/secret", nil)
+			m.ServeHTTP(record, req)
+
+			So(record.Code, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Convey("A request matching the prefix exactly requires credentials", func() {
+			record := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/admin", nil)
+			m.ServeHTTP(record, req)
+
+			So(record.Code, ShouldEqual, http.StatusUnauthorized)
+		})
+
+		Convey("A path that merely starts with the prefix's characters is not in scope", func() {
+			record := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/administrator", nil)
+			m.ServeHTTP(record, req)
+
+			So(record.Code, ShouldEqual, http.StatusOK)
+		})
+	})
+}
+
+func TestStaticCredentials(t *testing.T) {
+	Convey("StaticCredentials should only accept the exact username and password", t, func() {
+		checker := StaticCredentials("alice", "hunter2")
+
+		So(checker("alice", "hunter2"), ShouldBeTrue)
+		So(checker("alice", "hunter3"), ShouldBeFalse)
+		So(checker("bob", "hunter2"), ShouldBeFalse)
+		So(checker("", ""), ShouldBeFalse)
+	})
+}
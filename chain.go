@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Chain is an ordered list of middleware constructors, outermost first -
+// the same convention used by alice.  It gives callers a declarative way to
+// compose registry entries into a full handler instead of writing nested
+// calls by hand.
+type Chain []func(http.Handler) http.Handler
+
+// NewChain builds a Chain from the given middlewares, outermost first.
+func NewChain(middlewares ...func(http.Handler) http.Handler) Chain {
+	return append(Chain{}, middlewares...)
+}
+
+// Then wraps final with every middleware in the chain, outermost first, and
+// returns the resulting http.Handler.
+func (c Chain) Then(final http.Handler) http.Handler {
+	h := final
+	for i := len(c) - 1; i >= 0; i-- {
+		h = c[i](h)
+	}
+	return h
+}
+
+// ThenFunc is Then for an http.HandlerFunc final handler.
+func (c Chain) ThenFunc(final http.HandlerFunc) http.Handler {
+	return c.Then(final)
+}
+
+// Append returns a new Chain with middlewares added to the end (innermost
+// side) of c.  c is left unmodified.
+func (c Chain) Append(middlewares ...func(http.Handler) http.Handler) Chain {
+	newChain := make(Chain, 0, len(c)+len(middlewares))
+	newChain = append(newChain, c...)
+	newChain = append(newChain, middlewares...)
+	return newChain
+}
+
+// Extend returns a new Chain with the middlewares from other added to the
+// end (innermost side) of c.  c is left unmodified.
+func (c Chain) Extend(other Chain) Chain {
+	return c.Append(other...)
+}
+
+// BuildChain resolves each name via Get, composes them in order (outermost
+// first, like Chain.Then), and wraps final.  It returns an error naming the
+// first key that is not registered.
+func BuildChain(names []string, final http.Handler) (http.Handler, error) {
+	c := make(Chain, 0, len(names))
+	for _, name := range names {
+		f, ok := Get(name)
+		if !ok {
+			return nil, fmt.Errorf("middleware: no such registered middleware %q", name)
+		}
+		c = append(c, f)
+	}
+	return c.Then(final), nil
+}
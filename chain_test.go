@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func marker(name string, trail *[]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*trail = append(*trail, name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	Convey("A Chain runs its middlewares outermost-first", t, func() {
+		var trail []string
+		var final http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			trail = append(trail, "final")
+		}
+
+		c := NewChain(marker("a", &trail), marker("b", &trail))
+		record := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		c.Then(final).ServeHTTP(record, req)
+
+		So(trail, ShouldResemble, []string{"a", "b", "final"})
+	})
+
+	Convey("Append adds middlewares to the end without mutating the original chain", t, func() {
+		var trail []string
+		c1 := NewChain(marker("a", &trail))
+		c2 := c1.Append(marker("b", &trail))
+
+		So(len(c1), ShouldEqual, 1)
+		So(len(c2), ShouldEqual, 2)
+	})
+
+	Convey("Extend appends one chain's middlewares to another", t, func() {
+		var trail []string
+		c1 := NewChain(marker("a", &trail))
+		c2 := NewChain(marker("b", &trail))
+		c3 := c1.Extend(c2)
+
+		record := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		var final http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			trail = append(trail, "final")
+		}
+		c3.Then(final).ServeHTTP(record, req)
+
+		So(trail, ShouldResemble, []string{"a", "b", "final"})
+	})
+}
+
+func TestBuildChain(t *testing.T) {
+	Convey("BuildChain resolves names from the registry and composes them", t, func() {
+		var final http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}
+
+		h, err := BuildChain([]string{"middleware.Panic"}, final)
+		So(err, ShouldBeNil)
+		So(h, ShouldNotBeNil)
+
+		record := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		h.ServeHTTP(record, req)
+		So(record.Code, ShouldEqual, http.StatusOK)
+
+		Convey("An unknown name produces an error", func() {
+			_, err := BuildChain([]string{"middleware.DoesNotExist"}, final)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"bytes"
+	. "github.com/smartystreets/goconvey/convey"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStructuredLoggingMiddleware(t *testing.T) {
+	var okHandler http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("hello"))
+	}
+
+	Convey("The structured logging middleware should log a JSON record", t, func() {
+		buf := bytes.NewBuffer(make([]byte, 0, 100))
+		logger := NewWriterLogger(buf, LogFormatJSON)
+		m := NewStructuredLoggingMiddleware(logger, okHandler)
+
+		record := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/about/", nil)
+		if err != nil {
+			t.Fatalf("Unable to create test request")
+		}
+		m(record, req)
+
+		So(strings.Contains(buf.String(), `"path":"/about/"`), ShouldBeTrue)
+		So(strings.Contains(buf.String(), `"status":200`), ShouldBeTrue)
+		So(strings.Contains(buf.String(), `"bytes":5`), ShouldBeTrue)
+
+		Convey("A request ID placed on the context should be included", func() {
+			buf.Reset()
+			req = req.WithContext(WithRequestID(req.Context(), "abc123"))
+			m(record, req)
+			So(strings.Contains(buf.String(), `"request_id":"abc123"`), ShouldBeTrue)
+		})
+	})
+
+	Convey("The structured logging middleware should log a logfmt record", t, func() {
+		buf := bytes.NewBuffer(make([]byte, 0, 100))
+		logger := NewWriterLogger(buf, LogFormatLogfmt)
+		m := NewStructuredLoggingMiddleware(logger, okHandler)
+
+		record := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/about/", nil)
+		if err != nil {
+			t.Fatalf("Unable to create test request")
+		}
+		m(record, req)
+
+		So(strings.Contains(buf.String(), "path=/about/"), ShouldBeTrue)
+		So(strings.Contains(buf.String(), "status=200"), ShouldBeTrue)
+	})
+}
+
+func TestStdLogLogger(t *testing.T) {
+	Convey("StdLogLogger should write a logfmt line through the wrapped *log.Logger", t, func() {
+		buf := bytes.NewBuffer(make([]byte, 0, 100))
+		stdLogger := log.New(buf, "", 0)
+		logger := NewStdLogLogger(stdLogger)
+
+		logger.Log(map[string]any{"path": "/about/", "status": 200})
+
+		So(strings.Contains(buf.String(), "path=/about/"), ShouldBeTrue)
+		So(strings.Contains(buf.String(), "status=200"), ShouldBeTrue)
+	})
+}
+
+func TestSlogLogger(t *testing.T) {
+	Convey("SlogLogger should forward fields as attrs through the wrapped *slog.Logger", t, func() {
+		buf := bytes.NewBuffer(make([]byte, 0, 100))
+		handler := slog.NewTextHandler(buf, nil)
+		logger := NewSlogLogger(slog.New(handler))
+
+		logger.Log(map[string]any{"path": "/about/", "status": 200})
+
+		So(strings.Contains(buf.String(), "msg=request"), ShouldBeTrue)
+		So(strings.Contains(buf.String(), "path=/about/"), ShouldBeTrue)
+		So(strings.Contains(buf.String(), "status=200"), ShouldBeTrue)
+	})
+}
+
+func TestLogfmtValueQuoting(t *testing.T) {
+	Convey("Values containing spaces or quotes should be quoted", t, func() {
+		So(logfmtValue("plain"), ShouldEqual, "plain")
+		So(logfmtValue("has space"), ShouldEqual, `"has space"`)
+		So(logfmtValue(""), ShouldEqual, `""`)
+	})
+}
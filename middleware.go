@@ -6,16 +6,23 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"sync"
 	"time"
 )
 
-var registry map[string]func(http.Handler) http.Handler = make(map[string]func(http.Handler) http.Handler)
-var registryLock sync.RWMutex
+// StatusWriter is the stable, exported view of statusResponseWriter.  It lets
+// middleware further down the chain (panic reporters, structured loggers, ...)
+// recover the status code and byte count without depending on the unexported
+// type itself.
+type StatusWriter interface {
+	GetStatus() int
+	BytesWritten() int
+	Written() bool
+}
 
 type statusResponseWriter struct {
 	wrapped http.ResponseWriter
 	status  int
+	bytes   int
 }
 
 func (l *statusResponseWriter) Header() http.Header {
@@ -24,7 +31,9 @@ func (l *statusResponseWriter) Header() http.Header {
 
 func (l *statusResponseWriter) Write(data []byte) (int, error) {
 	l.setStatus(http.StatusOK)
-	return l.wrapped.Write(data)
+	n, err := l.wrapped.Write(data)
+	l.bytes += n
+	return n, err
 }
 
 func (l *statusResponseWriter) WriteHeader(statusValue int) {
@@ -45,6 +54,17 @@ func (l *statusResponseWriter) GetStatus() int {
 	return l.status
 }
 
+// BytesWritten reports the number of response body bytes written so far.
+func (l *statusResponseWriter) BytesWritten() int {
+	return l.bytes
+}
+
+// Written reports whether a status code (and potentially a body) has
+// already been sent to the underlying http.ResponseWriter.
+func (l *statusResponseWriter) Written() bool {
+	return l.status != 0
+}
+
 // Create a logging middleware.  It reports the http status code, url, and time taken to execute the request
 //
 // out - The io.Writer to log to
@@ -66,20 +86,6 @@ func NewLoggingMiddleware(out io.Writer, chain http.Handler) http.HandlerFunc {
 	}
 }
 
-// Create a handler to handle panics
-// Added to the registry as "middleware.Panic"
-func NewPanicMiddleware(chain http.Handler) http.Handler {
-	var f http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
-		defer func() {
-			if r := recover(); r != nil {
-				w.WriteHeader(500)
-			}
-		}()
-		chain.ServeHTTP(w, r)
-	}
-	return f
-}
-
 func init() {
 	Register("middleware.Panic", NewPanicMiddleware)
 	Register("middleware.LoggingStdOut", func(chain http.Handler) http.Handler {
@@ -88,42 +94,12 @@ func init() {
 	Register("middleware.LoggingStdErr", func(chain http.Handler) http.Handler {
 		return NewLoggingMiddleware(os.Stderr, chain)
 	})
-}
-
-// Add a middleware function to a global registry.
-// Duplicate keys are not allowed (and panic)
-// Nill entries are not added
-func Register(key string, f func(http.Handler) http.Handler) {
-	if f == nil {
-		return
-	}
-	registryLock.Lock()
-	defer registryLock.Unlock()
-
-	if _, ok := registry[key]; ok {
-		panic("Middleware registry key reused")
-	}
-
-	registry[key] = f
-}
-
-// Retreive a middleware function from the global registry
-// returns handler, bool.  True if there is a matching handler, esle false
-func Get(key string) (func(http.Handler) http.Handler, bool) {
-	registryLock.RLock()
-	defer registryLock.RUnlock()
-
-	f, ok := registry[key]
-	return f, ok
-}
-
-func MustGet(key string) func(http.Handler) http.Handler {
-	registryLock.RLock()
-	defer registryLock.RUnlock()
-
-	f := registry[key]
-	if f == nil {
-		panic("Invalid middleware requested")
-	}
-	return f
+	Register("middleware.LoggingJSON", func(chain http.Handler) http.Handler {
+		return NewStructuredLoggingMiddleware(NewWriterLogger(os.Stdout, LogFormatJSON), chain)
+	})
+	Register("middleware.LoggingLogfmt", func(chain http.Handler) http.Handler {
+		return NewStructuredLoggingMiddleware(NewWriterLogger(os.Stdout, LogFormatLogfmt), chain)
+	})
+	Register("middleware.PanicText", NewPanicMiddlewareWith(PanicOptions{Response: TextPanicResponse(defaultPanicMessage)}))
+	Register("middleware.PanicJSON", NewPanicMiddlewareWith(PanicOptions{Response: JSONPanicResponse(defaultPanicMessage)}))
 }
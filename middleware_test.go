@@ -5,6 +5,7 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -111,7 +112,7 @@ func TestInitalRegistryContents(t *testing.T) {
 
 	}
 	Convey("The registry should have some initial contents", t, func() {
-		tests := []string{"middleware.Panic", "middleware.LoggingStdOut", "middleware.LoggingStdErr"}
+		tests := []string{"middleware.Panic", "middleware.LoggingStdOut", "middleware.LoggingStdErr", "middleware.LoggingJSON", "middleware.LoggingLogfmt", "middleware.PanicText", "middleware.PanicJSON", "middleware.BasicAuth", "middleware.RateLimit"}
 
 		for _, test := range tests {
 			f, ok := Get(test)
@@ -134,56 +135,43 @@ func TestRegisterGetMustGet(t *testing.T) {
 		return next
 	}
 
-	registryLock.Lock()
-	oldReg := registry
-	registry = make(map[string]func(http.Handler) http.Handler)
-	registryLock.Unlock()
-
-	defer func() {
-		registryLock.Lock()
-		registry = oldReg
-		registryLock.Unlock()
-	}()
-
 	Convey("The register function allows you to register middleware components", t, func() {
 		Convey("Middleware is registered by name", func() {
-			registryLock.Lock()
-			registry = make(map[string]func(http.Handler) http.Handler)
-			registryLock.Unlock()
+			reg := NewRegistry()
 
-			Register("nillHandler", f)
+			reg.Register("nillHandler", f)
 			Convey("After a function is registered we should be able to retreive it", func() {
-				f1, ok := Get("nillHandler")
+				f1, ok := reg.Get("nillHandler")
 				So(f1, ShouldNotBeNil)
 				So(ok, ShouldBeTrue)
-				So(f1, ShouldEqual, f)
+				So(reflect.ValueOf(f1).Pointer(), ShouldEqual, reflect.ValueOf(f).Pointer())
 			})
 			Convey("Passing nil as a handler is a nop", func() {
-				Register("noop", nil)
-				f2, ok := Get("noop")
+				reg.Register("noop", nil)
+				f2, ok := reg.Get("noop")
 				So(f2, ShouldBeNil)
 				So(ok, ShouldBeFalse)
 			})
 			Convey("Registering a name twice panics", func() {
-				So(func() { Register("nillHandler", o) }, ShouldPanic)
+				So(func() { reg.Register("nillHandler", o) }, ShouldPanic)
 			})
 			Convey("Registering a name twice panics, even if it is the same value", func() {
-				So(func() { Register("nillHandler", f) }, ShouldPanic)
+				So(func() { reg.Register("nillHandler", f) }, ShouldPanic)
 			})
 			Convey("It is safe to call Get on a invalid string", func() {
-				f3, ok := Get("")
+				f3, ok := reg.Get("")
 				So(ok, ShouldBeFalse)
 				So(f3, ShouldBeNil)
 			})
 			Convey("MustGet also retreives middleware from the registry, but it panics if a match is not found", func() {
 				Convey("Getting a valid middleware should work", func() {
 					var f4 func(http.Handler) http.Handler
-					So(func() { f4 = MustGet("nillHandler") }, ShouldNotPanic)
+					So(func() { f4 = reg.MustGet("nillHandler") }, ShouldNotPanic)
 					So(f4, ShouldNotBeNil)
 				})
 				Convey("Calling MustGet a non-existant key should panic", func() {
 					var f5 func(http.Handler) http.Handler
-					So(func() { f5 = MustGet("non-existant") }, ShouldPanic)
+					So(func() { f5 = reg.MustGet("non-existant") }, ShouldPanic)
 					So(f5, ShouldBeNil)
 				})
 			})
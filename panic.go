@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"runtime/debug"
+)
+
+const defaultPanicMessage = "Internal Server Error"
+
+// PanicOptions configures NewPanicMiddlewareWith.
+type PanicOptions struct {
+	// Reporter, if set, is called with the recovered panic value and a
+	// stack trace captured at the point of recovery, before the response
+	// is written.
+	Reporter func(r *http.Request, val any, stack []byte)
+
+	// Response is served when a panic is recovered and no part of the
+	// response has been written yet.  It defaults to
+	// TextPanicResponse(defaultPanicMessage).
+	Response http.Handler
+}
+
+// TextPanicResponse returns a panic response handler that writes a 500
+// status with a plain text body.
+func TextPanicResponse(msg string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, msg)
+	})
+}
+
+// JSONPanicResponse returns a panic response handler that writes a 500
+// status with a JSON body of the form {"error": msg}.
+func JSONPanicResponse(msg string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": msg})
+	})
+}
+
+// NewPanicMiddlewareWith builds a panic-recovery middleware from opts.  It
+// recovers any panic raised further down the chain, captures a stack trace,
+// reports it via opts.Reporter (if set), and serves opts.Response - but only
+// if the wrapped handler had not already started writing a response.
+// http.ErrAbortHandler is re-panicked to preserve stdlib semantics.
+func NewPanicMiddlewareWith(opts PanicOptions) func(http.Handler) http.Handler {
+	response := opts.Response
+	if response == nil {
+		response = TextPanicResponse(defaultPanicMessage)
+	}
+
+	return func(chain http.Handler) http.Handler {
+		var f http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			statusWriter := &statusResponseWriter{wrapped: w}
+			defer func() {
+				val := recover()
+				if val == nil {
+					return
+				}
+				if val == http.ErrAbortHandler {
+					panic(val)
+				}
+
+				stack := debug.Stack()
+				if opts.Reporter != nil {
+					opts.Reporter(r, val, stack)
+				}
+				if !statusWriter.Written() {
+					response.ServeHTTP(statusWriter, r)
+				}
+			}()
+			chain.ServeHTTP(statusWriter, r)
+		}
+		return f
+	}
+}
+
+// Create a handler to handle panics
+// Added to the registry as "middleware.Panic"
+func NewPanicMiddleware(chain http.Handler) http.Handler {
+	return NewPanicMiddlewareWith(PanicOptions{})(chain)
+}
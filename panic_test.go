@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPanicMiddlewareWith(t *testing.T) {
+	Convey("NewPanicMiddlewareWith should recover panics and report them", t, func() {
+		var reportedVal any
+		var reportedStack []byte
+		opts := PanicOptions{
+			Reporter: func(r *http.Request, val any, stack []byte) {
+				reportedVal = val
+				reportedStack = stack
+			},
+		}
+
+		var doPanic http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			panic("bye bye")
+		}
+
+		m := NewPanicMiddlewareWith(opts)(doPanic)
+		record := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+
+		So(func() { m.ServeHTTP(record, req) }, ShouldNotPanic)
+		So(reportedVal, ShouldEqual, "bye bye")
+		So(len(reportedStack), ShouldBeGreaterThan, 0)
+		So(record.Code, ShouldEqual, http.StatusInternalServerError)
+
+		Convey("The default response body is plain text", func() {
+			So(strings.Contains(record.Body.String(), defaultPanicMessage), ShouldBeTrue)
+		})
+	})
+
+	Convey("JSONPanicResponse should emit a JSON error body", t, func() {
+		var doPanic http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}
+		m := NewPanicMiddlewareWith(PanicOptions{Response: JSONPanicResponse("boom")})(doPanic)
+		record := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+
+		m.ServeHTTP(record, req)
+		So(strings.Contains(record.Body.String(), `"error":"boom"`), ShouldBeTrue)
+	})
+
+	Convey("A response that has already started should not be overwritten", t, func() {
+		var doPanic http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("too late")
+		}
+		m := NewPanicMiddlewareWith(PanicOptions{})(doPanic)
+		record := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+
+		m.ServeHTTP(record, req)
+		So(record.Code, ShouldEqual, http.StatusAccepted)
+	})
+
+	Convey("http.ErrAbortHandler should be re-panicked", t, func() {
+		var doPanic http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}
+		m := NewPanicMiddlewareWith(PanicOptions{})(doPanic)
+		record := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+
+		So(func() { m.ServeHTTP(record, req) }, ShouldPanic)
+	})
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// KeyFunc derives the rate-limit bucket key for a request, e.g. the client
+// IP or an API key.
+type KeyFunc func(*http.Request) string
+
+// defaultKeyFunc buckets by the request's remote IP, stripping the port.
+func defaultKeyFunc(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimitOptions configures NewRateLimitMiddleware.
+type RateLimitOptions struct {
+	// Rate is the number of tokens added to a bucket per second.
+	Rate float64
+
+	// Burst is the maximum number of tokens a bucket can hold. Defaults
+	// to Rate if unset.
+	Burst float64
+
+	// KeyFunc derives the bucket key for a request. Defaults to the
+	// request's remote IP.
+	KeyFunc KeyFunc
+
+	// TTL is how long a bucket may sit idle before it is evicted by the
+	// background GC goroutine. Defaults to 5 minutes.
+	TTL time.Duration
+
+	// OnRejected, if set, replaces the default 429 response. It is
+	// called with the duration the client should wait before retrying.
+	OnRejected func(w http.ResponseWriter, r *http.Request, retryAfter time.Duration)
+}
+
+type rateLimitBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+type rateLimiter struct {
+	opts    RateLimitOptions
+	buckets sync.Map // string -> *rateLimitBucket
+	gcOnce  sync.Once
+}
+
+// allow consumes a token for key if one is available, refilling the bucket
+// lazily based on elapsed time. It reports whether the request should
+// proceed and, if not, how long the caller should wait before retrying.
+func (rl *rateLimiter) allow(key string) (bool, time.Duration) {
+	now := time.Now()
+	v, _ := rl.buckets.LoadOrStore(key, &rateLimitBucket{tokens: rl.opts.Burst, last: now})
+	b := v.(*rateLimitBucket)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(rl.opts.Burst, b.tokens+elapsed*rl.opts.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	deficit := 1 - b.tokens
+	retryAfter := time.Duration(math.Ceil(deficit / rl.opts.Rate * float64(time.Second)))
+	return false, retryAfter
+}
+
+// startGC lazily launches the background goroutine that evicts buckets idle
+// longer than opts.TTL, bounding memory use under many distinct keys.
+func (rl *rateLimiter) startGC() {
+	rl.gcOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(rl.opts.TTL)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				rl.buckets.Range(func(key, value any) bool {
+					b := value.(*rateLimitBucket)
+					b.mu.Lock()
+					idle := now.Sub(b.last)
+					b.mu.Unlock()
+					if idle > rl.opts.TTL {
+						rl.buckets.Delete(key)
+					}
+					return true
+				})
+			}
+		}()
+	})
+}
+
+// NewRateLimitMiddleware implements per-key token-bucket rate limiting.
+// Each key (by default, the client's remote IP) gets its own bucket that
+// refills at opts.Rate tokens per second up to opts.Burst tokens. Requests
+// that find an empty bucket are rejected with 429 Too Many Requests and a
+// Retry-After header, unless opts.OnRejected is set.
+func NewRateLimitMiddleware(opts RateLimitOptions) func(http.Handler) http.Handler {
+	if opts.Rate <= 0 {
+		panic("middleware: RateLimitOptions.Rate must be > 0")
+	}
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = defaultKeyFunc
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = opts.Rate
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = 5 * time.Minute
+	}
+
+	rl := &rateLimiter{opts: opts}
+
+	return func(chain http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rl.startGC()
+
+			key := opts.KeyFunc(r)
+			allowed, retryAfter := rl.allow(key)
+			if !allowed {
+				if opts.OnRejected != nil {
+					opts.OnRejected(w, r, retryAfter)
+					return
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+				return
+			}
+
+			chain.ServeHTTP(w, r)
+		})
+	}
+}
+
+func init() {
+	Register("middleware.RateLimit", NewRateLimitMiddleware(RateLimitOptions{
+		Rate:  10,
+		Burst: 20,
+	}))
+}
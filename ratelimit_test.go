@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	var okHandler http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	Convey("A non-positive Rate is a misconfiguration and panics", t, func() {
+		So(func() { NewRateLimitMiddleware(RateLimitOptions{Rate: 0}) }, ShouldPanic)
+		So(func() { NewRateLimitMiddleware(RateLimitOptions{Rate: -1}) }, ShouldPanic)
+	})
+
+	Convey("A bucket allows burst requests up to its capacity, then rejects", t, func() {
+		m := NewRateLimitMiddleware(RateLimitOptions{Rate: 1, Burst: 2})(okHandler)
+
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "1.2.3.4:1111"
+
+		record := httptest.NewRecorder()
+		m.ServeHTTP(record, req)
+		So(record.Code, ShouldEqual, http.StatusOK)
+
+		record = httptest.NewRecorder()
+		m.ServeHTTP(record, req)
+		So(record.Code, ShouldEqual, http.StatusOK)
+
+		record = httptest.NewRecorder()
+		m.ServeHTTP(record, req)
+		So(record.Code, ShouldEqual, http.StatusTooManyRequests)
+		So(record.Header().Get("Retry-After"), ShouldNotBeEmpty)
+	})
+
+	Convey("Different keys get independent buckets", t, func() {
+		m := NewRateLimitMiddleware(RateLimitOptions{Rate: 1, Burst: 1})(okHandler)
+
+		req1, _ := http.NewRequest("GET", "/", nil)
+		req1.RemoteAddr = "1.1.1.1:1111"
+		req2, _ := http.NewRequest("GET", "/", nil)
+		req2.RemoteAddr = "2.2.2.2:2222"
+
+		record1 := httptest.NewRecorder()
+		m.ServeHTTP(record1, req1)
+		So(record1.Code, ShouldEqual, http.StatusOK)
+
+		record2 := httptest.NewRecorder()
+		m.ServeHTTP(record2, req2)
+		So(record2.Code, ShouldEqual, http.StatusOK)
+	})
+
+	Convey("A custom OnRejected handler replaces the default 429 response", t, func() {
+		called := false
+		m := NewRateLimitMiddleware(RateLimitOptions{
+			Rate:  1,
+			Burst: 1,
+			OnRejected: func(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+				called = true
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+		})(okHandler)
+
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "3.3.3.3:3333"
+
+		m.ServeHTTP(httptest.NewRecorder(), req)
+		record := httptest.NewRecorder()
+		m.ServeHTTP(record, req)
+
+		So(called, ShouldBeTrue)
+		So(record.Code, ShouldEqual, http.StatusServiceUnavailable)
+	})
+
+	Convey("Concurrent requests against the same key should not over-admit", t, func() {
+		m := NewRateLimitMiddleware(RateLimitOptions{Rate: 1, Burst: 5})(okHandler)
+
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "4.4.4.4:4444"
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed := 0
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				record := httptest.NewRecorder()
+				m.ServeHTTP(record, req)
+				if record.Code == http.StatusOK {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		So(allowed, ShouldEqual, 5)
+	})
+}
+
+func TestRateLimiterEviction(t *testing.T) {
+	Convey("Idle buckets are evicted by the background GC", t, func() {
+		rl := &rateLimiter{opts: RateLimitOptions{Rate: 1, Burst: 1, TTL: 10 * time.Millisecond}}
+		rl.allow("stale-key")
+
+		if _, ok := rl.buckets.Load("stale-key"); !ok {
+			t.Fatalf("expected bucket to exist before eviction")
+		}
+
+		rl.startGC()
+		time.Sleep(50 * time.Millisecond)
+
+		_, ok := rl.buckets.Load("stale-key")
+		So(ok, ShouldBeFalse)
+	})
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Registry holds a named collection of middleware constructors. The
+// package-level Register/Get/MustGet/... functions delegate to
+// DefaultRegistry; use NewRegistry to get an isolated instance instead,
+// e.g. for tests or hot-reload scenarios that shouldn't disturb the
+// built-in entries.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]func(http.Handler) http.Handler
+}
+
+// NewRegistry returns an empty, isolated *Registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]func(http.Handler) http.Handler)}
+}
+
+// DefaultRegistry is where this package's own built-in middleware is
+// registered, and where the package-level Register/Get/... functions look.
+// Its methods are safe for concurrent use, but the DefaultRegistry variable
+// itself is not synchronized - it is meant to be read, not reassigned.
+// Tests and hot-reload scenarios that want an isolated registry should call
+// NewRegistry and operate on that value directly instead of swapping this
+// variable out from under concurrent callers.
+var DefaultRegistry = NewRegistry()
+
+// Register adds a middleware function to the registry under key.
+// Duplicate keys are not allowed (and panic). Nil entries are not added.
+func (reg *Registry) Register(key string, f func(http.Handler) http.Handler) {
+	if f == nil {
+		return
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if _, ok := reg.entries[key]; ok {
+		panic("Middleware registry key reused")
+	}
+
+	reg.entries[key] = f
+}
+
+// Get retrieves a middleware function from the registry.
+// Returns handler, bool - true if there is a matching handler, else false.
+func (reg *Registry) Get(key string) (func(http.Handler) http.Handler, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	f, ok := reg.entries[key]
+	return f, ok
+}
+
+// MustGet retrieves a middleware function from the registry, or panics if
+// key is not registered.
+func (reg *Registry) MustGet(key string) func(http.Handler) http.Handler {
+	f, ok := reg.Get(key)
+	if !ok {
+		panic("Invalid middleware requested")
+	}
+	return f
+}
+
+// Has reports whether key is currently registered.
+func (reg *Registry) Has(key string) bool {
+	_, ok := reg.Get(key)
+	return ok
+}
+
+// List returns a sorted snapshot of every registered key.
+func (reg *Registry) List() []string {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	keys := make([]string, 0, len(reg.entries))
+	for key := range reg.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Unregister removes key from the registry, if present. It reports whether
+// an entry was actually removed.
+func (reg *Registry) Unregister(key string) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if _, ok := reg.entries[key]; !ok {
+		return false
+	}
+	delete(reg.entries, key)
+	return true
+}
+
+// Replace sets key to f regardless of whether it was already registered,
+// returning the previous entry (if any) and whether one existed. Unlike
+// Register, Replace never panics on a duplicate key - it is meant for
+// tests and hot-reload scenarios where re-registration is expected.
+func (reg *Registry) Replace(key string, f func(http.Handler) http.Handler) (func(http.Handler) http.Handler, bool) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	previous, had := reg.entries[key]
+	reg.entries[key] = f
+	return previous, had
+}
+
+// Register adds a middleware function to DefaultRegistry.
+// Duplicate keys are not allowed (and panic).
+// Nill entries are not added.
+func Register(key string, f func(http.Handler) http.Handler) {
+	DefaultRegistry.Register(key, f)
+}
+
+// Get retrieves a middleware function from DefaultRegistry.
+// returns handler, bool.  True if there is a matching handler, esle false
+func Get(key string) (func(http.Handler) http.Handler, bool) {
+	return DefaultRegistry.Get(key)
+}
+
+// MustGet retrieves a middleware function from DefaultRegistry, or panics
+// if key is not registered.
+func MustGet(key string) func(http.Handler) http.Handler {
+	return DefaultRegistry.MustGet(key)
+}
+
+// Has reports whether key is registered in DefaultRegistry.
+func Has(key string) bool {
+	return DefaultRegistry.Has(key)
+}
+
+// List returns a sorted snapshot of every key registered in
+// DefaultRegistry.
+func List() []string {
+	return DefaultRegistry.List()
+}
+
+// Unregister removes key from DefaultRegistry, if present.
+func Unregister(key string) bool {
+	return DefaultRegistry.Unregister(key)
+}
+
+// Replace sets key to f in DefaultRegistry, returning the previous entry
+// (if any) and whether one existed.
+func Replace(key string, f func(http.Handler) http.Handler) (func(http.Handler) http.Handler, bool) {
+	return DefaultRegistry.Replace(key, f)
+}
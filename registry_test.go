@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func taggingMiddleware(tag string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Tag", tag)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestRegistryIntrospection(t *testing.T) {
+	var noopFinal http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {}
+
+	Convey("A fresh Registry is isolated from DefaultRegistry", t, func() {
+		reg := NewRegistry()
+		So(reg.Has("middleware.Panic"), ShouldBeFalse)
+
+		Convey("Has reports whether a key is registered", func() {
+			So(reg.Has("a"), ShouldBeFalse)
+			reg.Register("a", taggingMiddleware("a"))
+			So(reg.Has("a"), ShouldBeTrue)
+		})
+
+		Convey("List returns a sorted snapshot of registered keys", func() {
+			reg.Register("b", taggingMiddleware("b"))
+			reg.Register("a", taggingMiddleware("a"))
+			reg.Register("c", taggingMiddleware("c"))
+			So(reg.List(), ShouldResemble, []string{"a", "b", "c"})
+		})
+
+		Convey("Unregister removes an entry and reports whether it existed", func() {
+			reg.Register("a", taggingMiddleware("a"))
+			So(reg.Unregister("a"), ShouldBeTrue)
+			So(reg.Has("a"), ShouldBeFalse)
+			So(reg.Unregister("a"), ShouldBeFalse)
+		})
+
+		Convey("Replace overwrites an entry without panicking and exposes the previous one", func() {
+			reg.Register("a", taggingMiddleware("old"))
+			prev, had := reg.Replace("a", taggingMiddleware("new"))
+			So(had, ShouldBeTrue)
+
+			record := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			prev(noopFinal).ServeHTTP(record, req)
+			So(record.Header().Get("X-Tag"), ShouldEqual, "old")
+
+			f, _ := reg.Get("a")
+			record = httptest.NewRecorder()
+			f(noopFinal).ServeHTTP(record, req)
+			So(record.Header().Get("X-Tag"), ShouldEqual, "new")
+
+			Convey("Replacing an unregistered key reports that none existed", func() {
+				_, had := reg.Replace("z", taggingMiddleware("z"))
+				So(had, ShouldBeFalse)
+				So(reg.Has("z"), ShouldBeTrue)
+			})
+		})
+	})
+}